@@ -1,127 +1,204 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/sender"
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/suppress"
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/teams"
 	"github.com/sensu/sensu-go/types"
 	"github.com/spf13/cobra"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"time"
+)
+
+// Sensu annotations, read from the event's check and then entity, that
+// override the corresponding CLI flag for a single event.
+const (
+	annotationWebhookURL    = "sensu.io/plugins/teams/config/webhook-url"
+	annotationChannel       = "sensu.io/plugins/teams/config/channel"
+	annotationMessagePrefix = "sensu.io/plugins/teams/config/message-prefix"
+	annotationMentionUsers  = "sensu.io/plugins/teams/config/mention-users"
 )
 
 var (
-	webhookURL    string
-	channel       string
-	messagePrefix string
-	iconURL       string
-	actionName    string
-	dashboard     string
-	stdin         *os.File
+	webhookURLs        []string
+	format             string
+	channel            string
+	messagePrefix      string
+	iconURL            string
+	actionName         string
+	dashboard          string
+	silenceURL         string
+	mentionUsers       string
+	titleTemplate      string
+	titleTemplateFile  string
+	textTemplate       string
+	textTemplateFile   string
+	factsTemplate      string
+	factsTemplateFile  string
+	timeout            time.Duration
+	proxyURL           string
+	insecureSkipVerify bool
+	maxRetries         int
+	retryBackoff       time.Duration
+	filterStatus       string
+	minOccurrences     uint32
+	dedupWindow        time.Duration
+	stateFile          string
+	stdin              *os.File
+
+	cardTemplates *teams.Templates
 )
 
-type Section struct {
-	Text string `json:"text"`
+// cardOptions builds the teams.RenderOptions for event, applying any
+// per-event annotation overrides.
+func cardOptions(event *types.Event) teams.RenderOptions {
+	return teams.RenderOptions{
+		Channel:       annotationOrDefault(event, annotationChannel, channel),
+		MessagePrefix: annotationOrDefault(event, annotationMessagePrefix, messagePrefix),
+		ActionName:    actionName,
+		DashboardURL:  dashboard,
+		SilenceURL:    getSilenceLink(event),
+		Templates:     cardTemplates,
+		MentionUsers:  mentionUsersList(event),
+	}
 }
 
-type Target struct {
-	OS  string `json:"os"`
-	URI string `json:"uri"`
+// annotation looks up key on the event's check annotations, falling back to
+// the entity's annotations.
+func annotation(event *types.Event, key string) string {
+	if event.Check != nil {
+		if v, ok := event.Check.Annotations[key]; ok && v != "" {
+			return v
+		}
+	}
+	if event.Entity != nil {
+		if v, ok := event.Entity.Annotations[key]; ok && v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
-type PotentialAction struct {
-	Type string `json:"@type"`
-	Name string `json:"name"`
-
-	Targets []Target `json:"targets"`
+// annotationOrDefault returns the event's annotation value for key, or def
+// if the annotation is unset.
+func annotationOrDefault(event *types.Event, key, def string) string {
+	if v := annotation(event, key); v != "" {
+		return v
+	}
+	return def
 }
 
-type Message struct {
-	ThemeColor string `json:"themeColor"`
-	Text       string `json:"text"`
-	Channel    string `json:"channel"`
+// mentionUsersList resolves the comma-separated mention-users annotation or
+// flag into a list of UPNs/emails.
+func mentionUsersList(event *types.Event) []string {
+	value := annotationOrDefault(event, annotationMentionUsers, mentionUsers)
+	if value == "" {
+		return nil
+	}
 
-	Sections []Section `json:"section"`
+	var users []string
+	for _, user := range strings.Split(value, ",") {
+		if user = strings.TrimSpace(user); user != "" {
+			users = append(users, user)
+		}
+	}
 
-	PotentialAction []PotentialAction
+	return users
 }
 
-func NewEventMessage(event *types.Event) *Message {
-	message := &Message{ThemeColor: getColor(event), Text: getMessageStatus(event), Channel: channel} // TODO support channel from annotation
-	message.Sections = append(message.Sections, Section{event.Check.Output})
-	message.PotentialAction = append(message.PotentialAction, PotentialAction{Type: "OpenUri", Name: "View in Sensu"})
-	message.PotentialAction[0].Targets = append(message.PotentialAction[0].Targets, Target{"default", getLink(event)})
+// effectiveWebhookURLs returns the event's webhook-url annotation if set,
+// otherwise the --webhook-url flag values.
+func effectiveWebhookURLs(event *types.Event) []string {
+	if v := annotation(event, annotationWebhookURL); v != "" {
+		return []string{v}
+	}
+
+	var urls []string
+	for _, u := range webhookURLs {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
 
-	return message
+	return urls
 }
 
-func getLink(event *types.Event) string {
-	var (
-		dashboardUrl *url.URL
-		eventPath    *url.URL
-		err          error
-	)
+// buildSenders resolves the webhook url(s) for event and constructs a
+// Sender for each, so a single invocation can notify multiple
+// channels/tenants.
+func buildSenders(event *types.Event) ([]sender.Sender, error) {
+	urls := effectiveWebhookURLs(event)
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("webhook url is empty")
+	}
 
-	if dashboardUrl, err = url.Parse(dashboard); err != nil {
-		return ""
+	cfg := sender.Config{
+		CardOptions: cardOptions(event),
+		HTTP: sender.HTTPConfig{
+			Timeout:            timeout,
+			ProxyURL:           proxyURL,
+			InsecureSkipVerify: insecureSkipVerify,
+			MaxRetries:         maxRetries,
+			RetryBackoff:       retryBackoff,
+		},
 	}
 
-	if eventPath, err = url.Parse(event.URIPath()); err != nil {
-		return ""
+	senders := make([]sender.Sender, 0, len(urls))
+	for _, webhookURL := range urls {
+		s, err := sender.New(format, webhookURL, cfg)
+		if err != nil {
+			return nil, err
+		}
+		senders = append(senders, s)
 	}
 
-	return dashboardUrl.ResolveReference(eventPath).String()
+	return senders, nil
 }
 
-func getColor(event *types.Event) string {
-	switch event.Check.Status {
-	case 0:
-		return "#36A64F"
-	case 1:
-		return "#FFCC00"
-	case 2:
-		return "#FF0000"
-	default:
-		return "#6600CC"
+// loadTemplate returns the contents of file if it is set, otherwise value.
+func loadTemplate(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
 	}
-}
 
-func getMessageStatus(event *types.Event) string {
-	switch event.Check.Status {
-	case 0:
-		return "RESOLVED"
-	case 1:
-		return "WARNING"
-	case 2:
-		return "CRITICAL"
-	default:
-		return "UNKNOWN"
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template file %q: %s", file, err.Error())
 	}
+
+	return string(contents), nil
 }
 
-func sendMessage(event *types.Event) error {
-	var message = NewEventMessage(event)
-	var MessageString, _ = json.Marshal(message)
+func getSilenceLink(event *types.Event) string {
+	if silenceURL == "" {
+		return ""
+	}
 
-	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(MessageString))
-	req.Header.Set("Content-Type", "application/json")
+	var (
+		silenceBase *url.URL
+		silencePath *url.URL
+		err         error
+	)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		panic(err)
+	if silenceBase, err = url.Parse(silenceURL); err != nil {
+		return ""
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			panic(err)
-		}
-	}() // TODO: assert 200
 
-	return nil
+	// The "./" keeps "entity:check" a relative path; without it url.Parse
+	// reads the entity name as a URL scheme and the base url is dropped.
+	if silencePath, err = url.Parse(fmt.Sprintf("./%s:%s", event.Entity.Name, event.Check.Name)); err != nil {
+		return ""
+	}
+
+	return silenceBase.ResolveReference(silencePath).String()
 }
 
 func configureRootCommand() *cobra.Command {
@@ -131,11 +208,16 @@ func configureRootCommand() *cobra.Command {
 		RunE:  run,
 	}
 
-	cmd.Flags().StringVarP(&webhookURL,
+	cmd.Flags().StringArrayVarP(&webhookURLs,
 		"webhook-url",
 		"w",
-		os.Getenv("MS_TEAMS_WEBHOOK_URL"),
-		"The webhook url to send messages to")
+		defaultWebhookURLs(),
+		"The webhook url to send messages to; repeat to notify multiple channels/tenants")
+
+	cmd.Flags().StringVar(&format,
+		"format",
+		sender.FormatAuto,
+		"The webhook payload format: auto, messagecard, adaptivecard, generic, or stdout")
 
 	cmd.Flags().StringVarP(&channel,
 		"channel",
@@ -167,20 +249,109 @@ func configureRootCommand() *cobra.Command {
 		"",
 		"The url to the sensu dashboard")
 
+	cmd.Flags().StringVar(&silenceURL,
+		"silence-url",
+		os.Getenv("MS_TEAMS_SILENCE_URL"),
+		"The base url to silence the entity/check that triggered the event, resolved against entity:check")
+
+	cmd.Flags().DurationVar(&timeout,
+		"timeout",
+		10*time.Second,
+		"HTTP client timeout for delivering the message")
+
+	cmd.Flags().StringVar(&proxyURL,
+		"proxy-url",
+		os.Getenv("MS_TEAMS_PROXY_URL"),
+		"Proxy url to use when delivering the message")
+
+	cmd.Flags().BoolVar(&insecureSkipVerify,
+		"insecure-skip-verify",
+		false,
+		"Skip TLS certificate verification when delivering the message")
+
+	cmd.Flags().IntVar(&maxRetries,
+		"max-retries",
+		3,
+		"Maximum number of retries for 5xx/429/network errors")
+
+	cmd.Flags().DurationVar(&retryBackoff,
+		"retry-backoff",
+		time.Second,
+		"Base backoff duration between retries, doubled on each attempt")
+
+	cmd.Flags().StringVar(&filterStatus,
+		"filter-status",
+		os.Getenv("MS_TEAMS_FILTER_STATUS"),
+		"Comma-separated list of check statuses to notify on, e.g. \"1,2\"; empty notifies on every status")
+
+	cmd.Flags().Uint32Var(&minOccurrences,
+		"min-occurrences",
+		0,
+		"Suppress notification until the check has occurred at least this many times")
+
+	cmd.Flags().DurationVar(&dedupWindow,
+		"dedup-window",
+		0,
+		"Suppress a repeat notification for the same entity/check/status within this duration")
+
+	cmd.Flags().StringVar(&stateFile,
+		"state-file",
+		os.Getenv("MS_TEAMS_STATE_FILE"),
+		"Path to the on-disk cache file backing --dedup-window")
+
+	cmd.Flags().StringVar(&mentionUsers,
+		"mention-users",
+		os.Getenv("MS_TEAMS_MENTION_USERS"),
+		"Comma-separated list of UPNs/emails to @mention in the card text")
+
+	cmd.Flags().StringVar(&titleTemplate,
+		"title-template",
+		os.Getenv("MS_TEAMS_TITLE_TEMPLATE"),
+		"A Go text/template expression, evaluated against the event, used to render the card title")
+
+	cmd.Flags().StringVar(&titleTemplateFile,
+		"title-template-file",
+		os.Getenv("MS_TEAMS_TITLE_TEMPLATE_FILE"),
+		"A file containing the title template, overrides --title-template")
+
+	cmd.Flags().StringVar(&textTemplate,
+		"text-template",
+		os.Getenv("MS_TEAMS_TEXT_TEMPLATE"),
+		"A Go text/template expression, evaluated against the event, used to render the card text")
+
+	cmd.Flags().StringVar(&textTemplateFile,
+		"text-template-file",
+		os.Getenv("MS_TEAMS_TEXT_TEMPLATE_FILE"),
+		"A file containing the text template, overrides --text-template")
+
+	cmd.Flags().StringVar(&factsTemplate,
+		"facts-template",
+		os.Getenv("MS_TEAMS_FACTS_TEMPLATE"),
+		"A Go text/template expression, evaluated against the event, used to render the card facts, one \"Name: Value\" per line")
+
+	cmd.Flags().StringVar(&factsTemplateFile,
+		"facts-template-file",
+		os.Getenv("MS_TEAMS_FACTS_TEMPLATE_FILE"),
+		"A file containing the facts template, overrides --facts-template")
+
 	return cmd
 }
 
+// defaultWebhookURLs seeds --webhook-url from MS_TEAMS_WEBHOOK_URL so the
+// single-url, single-env-var usage this handler shipped with keeps working.
+func defaultWebhookURLs() []string {
+	if v := os.Getenv("MS_TEAMS_WEBHOOK_URL"); v != "" {
+		return []string{v}
+	}
+	return nil
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	if len(args) != 0 {
 		_ = cmd.Help()
 		return errors.New("invalid argument(s) received")
 	}
 
-	if webhookURL == "" {
-		_ = cmd.Help()
-		return fmt.Errorf("webhook url is empty")
-
-	}
 	if stdin == nil {
 		stdin = os.Stdin
 	}
@@ -204,8 +375,67 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err = sendMessage(event); err != nil {
-		return errors.New(err.Error())
+	statuses, err := suppress.ParseStatuses(filterStatus)
+	if err != nil {
+		return err
+	}
+
+	suppressCfg := suppress.Config{
+		Statuses:       statuses,
+		MinOccurrences: minOccurrences,
+		DedupWindow:    dedupWindow,
+		StateFile:      stateFile,
+	}
+
+	result, err := suppress.Check(event, suppressCfg)
+	if err != nil {
+		return err
+	}
+	if result.Suppressed {
+		log.Printf("suppressing notification for %s/%s: %s", event.Entity.Name, event.Check.Name, result.Reason)
+		return nil
+	}
+
+	title, err := loadTemplate(titleTemplate, titleTemplateFile)
+	if err != nil {
+		return err
+	}
+	text, err := loadTemplate(textTemplate, textTemplateFile)
+	if err != nil {
+		return err
+	}
+	facts, err := loadTemplate(factsTemplate, factsTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	if cardTemplates, err = teams.ParseTemplates(title, text, facts); err != nil {
+		return err
+	}
+
+	senders, err := buildSenders(event)
+	if err != nil {
+		_ = cmd.Help()
+		return err
+	}
+
+	var sendErrs []string
+	for _, s := range senders {
+		if err := s.Send(context.Background(), event); err != nil {
+			sendErrs = append(sendErrs, err.Error())
+		}
+	}
+	if len(sendErrs) > 0 {
+		return fmt.Errorf("failed to deliver %d/%d notification(s): %s", len(sendErrs), len(senders), strings.Join(sendErrs, "; "))
+	}
+
+	// Only record this notification as delivered once every sender has
+	// actually succeeded; a dedup entry written before delivery risks
+	// suppressing the retry of a notification that never went out. A
+	// failure here is logged, not returned: the notification itself
+	// succeeded, so it shouldn't be reported as a failed run.
+	if err := suppress.Commit(event, suppressCfg); err != nil {
+		log.Printf("failed to persist dedup state for %s/%s: %s", event.Entity.Name, event.Check.Name, err.Error())
 	}
 
 	return nil
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+func annotatedEvent(checkAnnotations, entityAnnotations map[string]string) *types.Event {
+	return &types.Event{
+		Entity: &types.Entity{
+			ObjectMeta: types.ObjectMeta{Name: "web-1", Annotations: entityAnnotations},
+		},
+		Check: &types.Check{
+			ObjectMeta: types.ObjectMeta{Name: "disk-usage", Annotations: checkAnnotations},
+		},
+	}
+}
+
+func TestAnnotationOrDefaultPrefersCheckOverEntity(t *testing.T) {
+	event := annotatedEvent(
+		map[string]string{annotationChannel: "#check-channel"},
+		map[string]string{annotationChannel: "#entity-channel"},
+	)
+
+	if got := annotationOrDefault(event, annotationChannel, "#default"); got != "#check-channel" {
+		t.Errorf("annotationOrDefault = %q, want check annotation to win", got)
+	}
+}
+
+func TestAnnotationOrDefaultFallsBackToEntity(t *testing.T) {
+	event := annotatedEvent(nil, map[string]string{annotationChannel: "#entity-channel"})
+
+	if got := annotationOrDefault(event, annotationChannel, "#default"); got != "#entity-channel" {
+		t.Errorf("annotationOrDefault = %q, want entity annotation", got)
+	}
+}
+
+func TestAnnotationOrDefaultFallsBackToDefault(t *testing.T) {
+	event := annotatedEvent(nil, nil)
+
+	if got := annotationOrDefault(event, annotationChannel, "#default"); got != "#default" {
+		t.Errorf("annotationOrDefault = %q, want the flag default", got)
+	}
+}
+
+func TestMentionUsersListParsesAnnotationOverride(t *testing.T) {
+	event := annotatedEvent(map[string]string{annotationMentionUsers: "alice@example.com, bob@example.com"}, nil)
+
+	got := mentionUsersList(event)
+	want := []string{"alice@example.com", "bob@example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mentionUsersList = %v, want %v", got, want)
+	}
+}
+
+func TestEffectiveWebhookURLsPrefersAnnotationOverFlags(t *testing.T) {
+	event := annotatedEvent(map[string]string{annotationWebhookURL: "https://example.com/override"}, nil)
+
+	webhookURLs = []string{"https://example.com/flag"}
+	defer func() { webhookURLs = nil }()
+
+	got := effectiveWebhookURLs(event)
+	want := []string{"https://example.com/override"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("effectiveWebhookURLs = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/teams"
+	"github.com/sensu/sensu-go/types"
+)
+
+// AdaptiveCardSender posts a Teams Workflows (Power Automate) Adaptive Card
+// incoming-webhook message.
+type AdaptiveCardSender struct {
+	WebhookURL  string
+	CardOptions teams.RenderOptions
+	HTTPConfig  HTTPConfig
+
+	client *http.Client
+}
+
+// NewAdaptiveCardSender builds an AdaptiveCardSender posting to webhookURL.
+func NewAdaptiveCardSender(webhookURL string, opts teams.RenderOptions, cfg HTTPConfig) (*AdaptiveCardSender, error) {
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdaptiveCardSender{WebhookURL: webhookURL, CardOptions: opts, HTTPConfig: cfg, client: client}, nil
+}
+
+// Send renders event as an Adaptive Card message and posts it to WebhookURL.
+func (s *AdaptiveCardSender) Send(ctx context.Context, event *types.Event) error {
+	message, err := teams.RenderAdaptive(event, s.CardOptions)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, s.client, s.WebhookURL, body, s.HTTPConfig)
+}
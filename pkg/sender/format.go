@@ -0,0 +1,78 @@
+package sender
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/teams"
+)
+
+// Payload formats supported by New.
+const (
+	FormatAuto         = "auto"
+	FormatMessageCard  = "messagecard"
+	FormatAdaptiveCard = "adaptivecard"
+	FormatGeneric      = "generic"
+	FormatStdout       = "stdout"
+)
+
+// DetectFormat guesses a webhook's payload format from its URL host. This
+// exists because Microsoft is retiring Office 365 Connector webhooks
+// (*.webhook.office.com) in favor of Teams Workflows, which are Power
+// Automate endpoints hosted on *.logic.azure.com.
+func DetectFormat(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return FormatMessageCard
+	}
+
+	host := strings.ToLower(u.Hostname())
+
+	switch {
+	case strings.HasSuffix(host, ".webhook.office.com"), strings.HasSuffix(host, "outlook.office.com"):
+		return FormatMessageCard
+	case strings.HasSuffix(host, "logic.azure.com"):
+		return FormatAdaptiveCard
+	default:
+		return FormatGeneric
+	}
+}
+
+// Config groups everything needed to construct any Sender implementation.
+type Config struct {
+	CardOptions teams.RenderOptions
+	HTTP        HTTPConfig
+	// Writer is used by the stdout format; it defaults to os.Stdout.
+	Writer io.Writer
+}
+
+// New builds the Sender for format and webhookURL. format == FormatAuto (or
+// "") resolves via DetectFormat. webhookURL == "-" always yields a stdout
+// sender, regardless of format, for local debugging.
+func New(format, webhookURL string, cfg Config) (Sender, error) {
+	if webhookURL == "-" {
+		format = FormatStdout
+	} else if format == "" || format == FormatAuto {
+		format = DetectFormat(webhookURL)
+	}
+
+	switch format {
+	case FormatMessageCard:
+		return NewMessageCardSender(webhookURL, cfg.CardOptions, cfg.HTTP)
+	case FormatAdaptiveCard:
+		return NewAdaptiveCardSender(webhookURL, cfg.CardOptions, cfg.HTTP)
+	case FormatGeneric:
+		return NewGenericSender(webhookURL, cfg.HTTP)
+	case FormatStdout:
+		writer := cfg.Writer
+		if writer == nil {
+			writer = os.Stdout
+		}
+		return &StdoutSender{Writer: writer, CardOptions: cfg.CardOptions}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
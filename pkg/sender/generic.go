@@ -0,0 +1,37 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// GenericSender posts the raw Sensu event as JSON to a plain webhook.
+type GenericSender struct {
+	WebhookURL string
+	HTTPConfig HTTPConfig
+
+	client *http.Client
+}
+
+// NewGenericSender builds a GenericSender posting to webhookURL.
+func NewGenericSender(webhookURL string, cfg HTTPConfig) (*GenericSender, error) {
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenericSender{WebhookURL: webhookURL, HTTPConfig: cfg, client: client}, nil
+}
+
+// Send posts event as JSON to WebhookURL.
+func (s *GenericSender) Send(ctx context.Context, event *types.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, s.client, s.WebhookURL, body, s.HTTPConfig)
+}
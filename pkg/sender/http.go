@@ -0,0 +1,138 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ResponseError is returned when a webhook responds with a terminal non-2xx status.
+type ResponseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("webhook returned %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTPConfig configures the retrying HTTP client shared by the webhook-based senders.
+type HTTPConfig struct {
+	Timeout            time.Duration
+	ProxyURL           string
+	InsecureSkipVerify bool
+	MaxRetries         int
+	RetryBackoff       time.Duration
+}
+
+// NewHTTPClient builds an *http.Client honoring cfg's timeout, proxy, and TLS settings.
+func NewHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	transport := &http.Transport{}
+
+	if cfg.ProxyURL != "" {
+		parsed, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy url: %s", err.Error())
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	}
+
+	if cfg.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}, nil
+}
+
+// postWithRetry POSTs body to webhookURL, retrying on 5xx/429 responses and
+// network errors up to cfg.MaxRetries times with exponential backoff,
+// honoring a Retry-After response header when present.
+func postWithRetry(ctx context.Context, client *http.Client, webhookURL string, body []byte, cfg HTTPConfig) error {
+	maxRetries := cfg.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	var (
+		lastErr error
+		wait    time.Duration
+	)
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to build request: %s", err.Error())
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			wait = nextBackoff(cfg, attempt, 0)
+			continue
+		}
+
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		lastErr = &ResponseError{StatusCode: resp.StatusCode, Body: string(respBody)}
+
+		if !isRetryableStatus(resp.StatusCode) {
+			return lastErr
+		}
+
+		wait = nextBackoff(cfg, attempt, retryAfter(resp.Header.Get("Retry-After")))
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no attempt was made")
+	}
+
+	return fmt.Errorf("giving up after %d attempt(s): %s", maxRetries+1, lastErr.Error())
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryAfter parses a Retry-After header, either delta-seconds or an
+// HTTP-date, returning 0 if it is absent or unparseable.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func nextBackoff(cfg HTTPConfig, attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	return cfg.RetryBackoff * time.Duration(math.Pow(2, float64(attempt)))
+}
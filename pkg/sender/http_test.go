@@ -0,0 +1,166 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPostWithRetrySucceedsAfterTransientStatuses(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	cfg := HTTPConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned error: %s", err.Error())
+	}
+
+	if err := postWithRetry(context.Background(), client, server.URL, []byte(`{}`), cfg); err != nil {
+		t.Fatalf("postWithRetry returned error: %s", err.Error())
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected 3 requests (429, 503, 200), got %d", got)
+	}
+}
+
+func TestPostWithRetryReturnsOnTerminalStatus(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg := HTTPConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}
+	client, _ := NewHTTPClient(cfg)
+
+	err := postWithRetry(context.Background(), client, server.URL, []byte(`{}`), cfg)
+	if err == nil {
+		t.Fatal("expected an error for a terminal 400 response")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected a non-retryable status to stop after 1 request, got %d", got)
+	}
+}
+
+func TestPostWithRetryGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := HTTPConfig{MaxRetries: 2, RetryBackoff: time.Millisecond}
+	client, _ := NewHTTPClient(cfg)
+
+	err := postWithRetry(context.Background(), client, server.URL, []byte(`{}`), cfg)
+	if err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected 1 initial attempt + 2 retries = 3 requests, got %d", got)
+	}
+}
+
+func TestPostWithRetryNegativeMaxRetriesDoesNotPanic(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := HTTPConfig{MaxRetries: -1, RetryBackoff: time.Millisecond}
+	client, _ := NewHTTPClient(cfg)
+
+	err := postWithRetry(context.Background(), client, server.URL, []byte(`{}`), cfg)
+	if err == nil {
+		t.Fatal("expected an error for a persistent 503")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected a negative --max-retries to behave like 0 retries (1 request), got %d", got)
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusBadRequest:          false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+	}
+
+	for status, want := range cases {
+		if got := isRetryableStatus(status); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter(""); got != 0 {
+		t.Errorf("retryAfter(\"\") = %s, want 0", got)
+	}
+
+	if got := retryAfter("5"); got != 5*time.Second {
+		t.Errorf("retryAfter(\"5\") = %s, want 5s", got)
+	}
+
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	got := retryAfter(future)
+	if got <= 0 || got > 10*time.Second {
+		t.Errorf("retryAfter(%q) = %s, want roughly 10s", future, got)
+	}
+
+	if got := retryAfter("not-a-valid-header"); got != 0 {
+		t.Errorf("retryAfter(garbage) = %s, want 0", got)
+	}
+}
+
+func TestPostWithRetryHonorsRetryAfterDelay(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", strconv.Itoa(0))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := HTTPConfig{MaxRetries: 1, RetryBackoff: time.Millisecond}
+	client, _ := NewHTTPClient(cfg)
+
+	start := time.Now()
+	if err := postWithRetry(context.Background(), client, server.URL, []byte(`{}`), cfg); err != nil {
+		t.Fatalf("postWithRetry returned error: %s", err.Error())
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("expected Retry-After: 0 to avoid a long backoff wait")
+	}
+}
@@ -0,0 +1,44 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/teams"
+	"github.com/sensu/sensu-go/types"
+)
+
+// MessageCardSender posts a legacy Office 365 Connector MessageCard.
+type MessageCardSender struct {
+	WebhookURL  string
+	CardOptions teams.RenderOptions
+	HTTPConfig  HTTPConfig
+
+	client *http.Client
+}
+
+// NewMessageCardSender builds a MessageCardSender posting to webhookURL.
+func NewMessageCardSender(webhookURL string, opts teams.RenderOptions, cfg HTTPConfig) (*MessageCardSender, error) {
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MessageCardSender{WebhookURL: webhookURL, CardOptions: opts, HTTPConfig: cfg, client: client}, nil
+}
+
+// Send renders event as a MessageCard and posts it to WebhookURL.
+func (s *MessageCardSender) Send(ctx context.Context, event *types.Event) error {
+	card, err := teams.Render(event, s.CardOptions)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	return postWithRetry(ctx, s.client, s.WebhookURL, body, s.HTTPConfig)
+}
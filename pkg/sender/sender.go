@@ -0,0 +1,13 @@
+// Package sender fans out Sensu event notifications to one or more sinks.
+package sender
+
+import (
+	"context"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// Sender delivers a notification for a Sensu event to a single sink.
+type Sender interface {
+	Send(ctx context.Context, event *types.Event) error
+}
@@ -0,0 +1,34 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/paynejacob/sensu-microsoft-teams-handler/pkg/teams"
+	"github.com/sensu/sensu-go/types"
+)
+
+// StdoutSender writes the rendered MessageCard to Writer for debugging,
+// instead of delivering it anywhere.
+type StdoutSender struct {
+	Writer      io.Writer
+	CardOptions teams.RenderOptions
+}
+
+// Send renders event as a MessageCard and writes it to Writer.
+func (s *StdoutSender) Send(ctx context.Context, event *types.Event) error {
+	card, err := teams.Render(event, s.CardOptions)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.MarshalIndent(card, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(s.Writer, string(body))
+	return err
+}
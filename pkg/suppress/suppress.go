@@ -0,0 +1,219 @@
+// Package suppress decides whether a Sensu event notification should be
+// suppressed, to avoid flooding a Teams channel during incident storms.
+package suppress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// Config controls event suppression.
+type Config struct {
+	// Statuses, when non-empty, restricts notifications to these check
+	// statuses. Empty means notify on every status.
+	Statuses []uint32
+	// MinOccurrences suppresses notification until the check has occurred at
+	// least this many times.
+	MinOccurrences uint32
+	// DedupWindow suppresses a repeat notification for the same
+	// entity+check+status within this duration. Zero disables dedup.
+	DedupWindow time.Duration
+	// StateFile persists the dedup cache across invocations. Required when
+	// DedupWindow is non-zero.
+	StateFile string
+}
+
+// Result describes a suppression decision.
+type Result struct {
+	Suppressed bool
+	Reason     string
+}
+
+// ParseStatuses parses a comma-separated list of check statuses, e.g. "1,2".
+func ParseStatuses(value string) ([]uint32, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var statuses []uint32
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status %q: %s", s, err.Error())
+		}
+
+		statuses = append(statuses, uint32(n))
+	}
+
+	return statuses, nil
+}
+
+// Check decides whether event should be suppressed. It only reads the
+// on-disk dedup cache at cfg.StateFile; it never records event as sent.
+// Call Commit once a notification for event has actually been delivered, so
+// that a later Check can dedup against it.
+func Check(event *types.Event, cfg Config) (Result, error) {
+	if len(cfg.Statuses) > 0 && !containsStatus(cfg.Statuses, event.Check.Status) {
+		return Result{
+			Suppressed: true,
+			Reason:     fmt.Sprintf("status %d is not in --filter-status", event.Check.Status),
+		}, nil
+	}
+
+	if cfg.MinOccurrences > 0 && uint32(event.Check.Occurrences) < cfg.MinOccurrences {
+		return Result{
+			Suppressed: true,
+			Reason:     fmt.Sprintf("occurrences %d is below --min-occurrences %d", event.Check.Occurrences, cfg.MinOccurrences),
+		}, nil
+	}
+
+	if cfg.DedupWindow > 0 {
+		if cfg.StateFile == "" {
+			return Result{}, fmt.Errorf("--dedup-window requires --state-file")
+		}
+
+		if last, ok := loadState(cfg.StateFile)[dedupKey(event)]; ok && time.Since(last) < cfg.DedupWindow {
+			return Result{
+				Suppressed: true,
+				Reason:     fmt.Sprintf("identical notification sent within --dedup-window %s", cfg.DedupWindow),
+			}, nil
+		}
+	}
+
+	return Result{}, nil
+}
+
+// Commit records that a notification for event was just delivered, so a
+// later Check call can dedup a repeat within cfg.DedupWindow. Call it only
+// after a send has actually succeeded; a premature Commit would suppress
+// the retry of a notification that never went out.
+//
+// A failure to persist is deliberately non-fatal to the caller: the worst
+// consequence is a duplicate notification, which is far preferable to
+// losing one because of a transient state-file problem.
+func Commit(event *types.Event, cfg Config) error {
+	if cfg.DedupWindow <= 0 || cfg.StateFile == "" {
+		return nil
+	}
+
+	return withStateLock(cfg.StateFile, func() error {
+		s := loadState(cfg.StateFile)
+		s[dedupKey(event)] = time.Now()
+		return saveState(cfg.StateFile, s)
+	})
+}
+
+func containsStatus(statuses []uint32, status uint32) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupKey identifies a notification for dedup purposes.
+func dedupKey(event *types.Event) string {
+	return fmt.Sprintf("%s/%s/%d", event.Entity.Name, event.Check.Name, event.Check.Status)
+}
+
+// state is the on-disk shape of the dedup cache file: dedup key to the time
+// a notification was last sent for it.
+type state map[string]time.Time
+
+// loadState reads the dedup cache at path, best-effort. A missing file reads
+// as empty state, and so does one that fails to parse: a torn write from a
+// prior crash or an incompatible format should never be allowed to take
+// down every future notification, since the cache is just an optimization
+// and the next successful Commit will overwrite it anyway.
+func loadState(path string) state {
+	s := state{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return s
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return state{}
+	}
+
+	return s
+}
+
+// saveState atomically replaces the dedup cache at path: it writes to a
+// temp file in the same directory and renames it into place, so a reader
+// (or a concurrently racing writer) never observes a partially written
+// file.
+func saveState(path string, s state) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %s", err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp state file: %s", err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temp state file: %s", err.Error())
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to set state file permissions: %s", err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace state file: %s", err.Error())
+	}
+
+	return nil
+}
+
+// withStateLock serializes Commit's read-modify-write of path across the
+// concurrent, one-process-per-event invocations Sensu uses, via a sibling
+// lock file created with O_EXCL. This is cooperative locking between
+// instances of this handler, not a general-purpose filesystem lock.
+func withStateLock(path string, fn func() error) error {
+	lockPath := path + ".lock"
+
+	var lock *os.File
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			lock = f
+			break
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to acquire state file lock: %s", err.Error())
+		}
+		if attempt >= 50 {
+			return fmt.Errorf("timed out waiting for state file lock %s", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer func() {
+		_ = lock.Close()
+		_ = os.Remove(lockPath)
+	}()
+
+	return fn()
+}
@@ -0,0 +1,225 @@
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+func testEvent(status uint32, occurrences int64) *types.Event {
+	return &types.Event{
+		Entity: &types.Entity{ObjectMeta: types.ObjectMeta{Name: "web-1"}},
+		Check: &types.Check{
+			ObjectMeta:  types.ObjectMeta{Name: "disk-usage"},
+			Status:      status,
+			Occurrences: occurrences,
+		},
+	}
+}
+
+func TestParseStatuses(t *testing.T) {
+	got, err := ParseStatuses(" 1, 2 ,2")
+	if err != nil {
+		t.Fatalf("ParseStatuses returned error: %s", err.Error())
+	}
+	want := []uint32{1, 2, 2}
+	if len(got) != len(want) {
+		t.Fatalf("ParseStatuses = %v, want %v", got, want)
+	}
+
+	if _, err := ParseStatuses("1,not-a-number"); err == nil {
+		t.Fatal("expected an error for a non-numeric status")
+	}
+}
+
+func TestCheckFiltersByStatus(t *testing.T) {
+	result, err := Check(testEvent(0, 1), Config{Statuses: []uint32{1, 2}})
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if !result.Suppressed {
+		t.Error("expected a status outside --filter-status to be suppressed")
+	}
+
+	result, err = Check(testEvent(1, 1), Config{Statuses: []uint32{1, 2}})
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if result.Suppressed {
+		t.Error("expected a status inside --filter-status to not be suppressed")
+	}
+}
+
+func TestCheckSuppressesBelowMinOccurrences(t *testing.T) {
+	result, err := Check(testEvent(1, 2), Config{MinOccurrences: 5})
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if !result.Suppressed {
+		t.Error("expected occurrences below --min-occurrences to be suppressed")
+	}
+
+	result, err = Check(testEvent(1, 5), Config{MinOccurrences: 5})
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if result.Suppressed {
+		t.Error("expected occurrences at --min-occurrences to not be suppressed")
+	}
+}
+
+func TestCheckRequiresStateFileForDedupWindow(t *testing.T) {
+	if _, err := Check(testEvent(1, 1), Config{DedupWindow: time.Minute}); err == nil {
+		t.Fatal("expected an error when --dedup-window is set without --state-file")
+	}
+}
+
+func TestCheckAndCommitDedupRoundTrip(t *testing.T) {
+	cfg := Config{
+		DedupWindow: time.Hour,
+		StateFile:   filepath.Join(t.TempDir(), "state.json"),
+	}
+	event := testEvent(1, 1)
+
+	result, err := Check(event, cfg)
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if result.Suppressed {
+		t.Fatal("expected the first notification to not be suppressed")
+	}
+
+	if err := Commit(event, cfg); err != nil {
+		t.Fatalf("Commit returned error: %s", err.Error())
+	}
+
+	result, err = Check(event, cfg)
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if !result.Suppressed {
+		t.Fatal("expected a repeat notification within --dedup-window to be suppressed")
+	}
+
+	// A different check is a different dedup key and should not be affected.
+	other := testEvent(1, 1)
+	other.Check.Name = "cpu-usage"
+	result, err = Check(other, cfg)
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if result.Suppressed {
+		t.Fatal("expected a different entity/check/status to not be suppressed")
+	}
+}
+
+func TestCheckDedupWindowExpires(t *testing.T) {
+	cfg := Config{
+		DedupWindow: time.Millisecond,
+		StateFile:   filepath.Join(t.TempDir(), "state.json"),
+	}
+	event := testEvent(1, 1)
+
+	if err := Commit(event, cfg); err != nil {
+		t.Fatalf("Commit returned error: %s", err.Error())
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	result, err := Check(event, cfg)
+	if err != nil {
+		t.Fatalf("Check returned error: %s", err.Error())
+	}
+	if result.Suppressed {
+		t.Fatal("expected a notification outside --dedup-window to not be suppressed")
+	}
+}
+
+func TestCommitIsNoopWithoutDedupWindowOrStateFile(t *testing.T) {
+	if err := Commit(testEvent(1, 1), Config{}); err != nil {
+		t.Fatalf("Commit returned error: %s", err.Error())
+	}
+}
+
+func TestLoadStateMissingFileIsEmpty(t *testing.T) {
+	s := loadState(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	if len(s) != 0 {
+		t.Errorf("loadState(missing) = %v, want empty state", s)
+	}
+}
+
+func TestLoadStateCorruptFileIsEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatalf("failed to seed corrupt state file: %s", err.Error())
+	}
+
+	s := loadState(path)
+	if len(s) != 0 {
+		t.Errorf("loadState(corrupt) = %v, want empty state", s)
+	}
+}
+
+func TestSaveStateIsAtomicAndReadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	want := state{"web-1/disk-usage/1": time.Now().Truncate(time.Second)}
+
+	if err := saveState(path, want); err != nil {
+		t.Fatalf("saveState returned error: %s", err.Error())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read state dir: %s", err.Error())
+	}
+	for _, e := range entries {
+		if e.Name() != filepath.Base(path) {
+			t.Errorf("expected saveState to leave no temp files behind, found %q", e.Name())
+		}
+	}
+
+	got := loadState(path)
+	if !got["web-1/disk-usage/1"].Equal(want["web-1/disk-usage/1"]) {
+		t.Errorf("loadState = %v, want %v", got, want)
+	}
+}
+
+func TestWithStateLockSerializesConcurrentCommits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	cfg := Config{DedupWindow: time.Hour, StateFile: path}
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			event := testEvent(1, 1)
+			event.Check.Name = string(rune('a' + i))
+			errs <- Commit(event, cfg)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Commit returned error: %s", err.Error())
+		}
+	}
+
+	s := loadState(path)
+	if len(s) != workers {
+		t.Fatalf("expected %d distinct dedup keys after %d concurrent commits, got %d: %v", workers, workers, len(s), s)
+	}
+
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Error("expected the lock file to be removed once all commits finished")
+	}
+}
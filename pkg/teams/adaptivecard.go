@@ -0,0 +1,110 @@
+package teams
+
+import "github.com/sensu/sensu-go/types"
+
+const (
+	adaptiveCardType    = "AdaptiveCard"
+	adaptiveCardVersion = "1.5"
+	adaptiveCardSchema  = "http://adaptivecards.io/schemas/adaptive-card.json"
+)
+
+// AdaptiveCardMessage is the Teams Workflows / Power Automate incoming
+// webhook payload: an Adaptive Card wrapped in a "message" activity.
+type AdaptiveCardMessage struct {
+	Type        string       `json:"type"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Attachment wraps an AdaptiveCard for delivery in an AdaptiveCardMessage.
+type Attachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard is an Adaptive Card 1.5 document.
+//
+// See: https://adaptivecards.io/explorer/AdaptiveCard.html
+type AdaptiveCard struct {
+	Type    string                `json:"type"`
+	Version string                `json:"version"`
+	Schema  string                `json:"$schema"`
+	Body    []AdaptiveCardElement `json:"body"`
+	Actions []AdaptiveCardAction  `json:"actions,omitempty"`
+	MSTeams *MSTeams              `json:"msteams,omitempty"`
+}
+
+// AdaptiveCardElement is a single card body element, e.g. "TextBlock" or "FactSet".
+type AdaptiveCardElement struct {
+	Type   string             `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Weight string             `json:"weight,omitempty"`
+	Size   string             `json:"size,omitempty"`
+	Wrap   bool               `json:"wrap,omitempty"`
+	Facts  []AdaptiveCardFact `json:"facts,omitempty"`
+}
+
+// AdaptiveCardFact is a single FactSet entry.
+type AdaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// AdaptiveCardAction is a card action, e.g. "Action.OpenUrl".
+type AdaptiveCardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// RenderAdaptive builds a Teams Workflows message containing an Adaptive
+// Card describing the given Sensu event.
+func RenderAdaptive(event *types.Event, opts RenderOptions) (*AdaptiveCardMessage, error) {
+	c, err := renderContent(event, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	body := []AdaptiveCardElement{
+		{Type: "TextBlock", Text: c.title, Weight: "Bolder", Size: "Medium", Wrap: true},
+		{Type: "TextBlock", Text: c.text, Wrap: true},
+	}
+
+	if len(c.facts) > 0 {
+		cardFacts := make([]AdaptiveCardFact, len(c.facts))
+		for i, f := range c.facts {
+			cardFacts[i] = AdaptiveCardFact{Title: f.Name, Value: f.Value}
+		}
+		body = append(body, AdaptiveCardElement{Type: "FactSet", Facts: cardFacts})
+	}
+
+	actions := []AdaptiveCardAction{
+		{Type: "Action.OpenUrl", Title: opts.ActionName, URL: dashboardLink(opts.DashboardURL, event)},
+	}
+
+	if opts.SilenceURL != "" {
+		name := opts.SilenceActionName
+		if name == "" {
+			name = "Silence"
+		}
+		actions = append(actions, AdaptiveCardAction{Type: "Action.OpenUrl", Title: name, URL: opts.SilenceURL})
+	}
+
+	card := AdaptiveCard{
+		Type:    adaptiveCardType,
+		Version: adaptiveCardVersion,
+		Schema:  adaptiveCardSchema,
+		Body:    body,
+		Actions: actions,
+	}
+
+	if len(c.entities) > 0 {
+		card.MSTeams = &MSTeams{Entities: c.entities}
+	}
+
+	return &AdaptiveCardMessage{
+		Type: "message",
+		Attachments: []Attachment{
+			{ContentType: "application/vnd.microsoft.card.adaptive", Content: card},
+		},
+	}, nil
+}
@@ -0,0 +1,261 @@
+// Package teams builds Microsoft Teams message payloads from Sensu events.
+package teams
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+const (
+	cardType    = "MessageCard"
+	cardContext = "https://schema.org/extensions"
+)
+
+// MessageCard is an Office 365 Connector "MessageCard" payload.
+//
+// See: https://docs.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+type MessageCard struct {
+	Type            string            `json:"@type"`
+	Context         string            `json:"@context"`
+	Summary         string            `json:"summary"`
+	ThemeColor      string            `json:"themeColor,omitempty"`
+	Text            string            `json:"text,omitempty"`
+	Channel         string            `json:"channel,omitempty"`
+	Sections        []Section         `json:"sections,omitempty"`
+	PotentialAction []PotentialAction `json:"potentialAction,omitempty"`
+	MSTeams         *MSTeams          `json:"msteams,omitempty"`
+}
+
+// MSTeams carries Teams-specific MessageCard extensions, currently just
+// @mention entities.
+//
+// See: https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using#mention-support-for-connectors-incoming-webhooks
+type MSTeams struct {
+	Entities []MentionEntity `json:"entities,omitempty"`
+}
+
+// MentionEntity is a single @mention; its Text is an "<at>...</at>" tag that
+// must also appear verbatim somewhere in the card body for Teams to render it.
+type MentionEntity struct {
+	Type      string    `json:"type"`
+	Text      string    `json:"text"`
+	Mentioned Mentioned `json:"mentioned"`
+}
+
+// Mentioned identifies the mentioned user.
+type Mentioned struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Section is a single MessageCard section.
+type Section struct {
+	ActivityTitle string `json:"activityTitle,omitempty"`
+	Text          string `json:"text,omitempty"`
+	Facts         []Fact `json:"facts,omitempty"`
+}
+
+// Fact is a name/value pair rendered in a Section's facts table.
+type Fact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Target is a single potentialAction target.
+type Target struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// PotentialAction is a MessageCard action, e.g. "OpenUri".
+type PotentialAction struct {
+	Type    string   `json:"@type"`
+	Name    string   `json:"name"`
+	Targets []Target `json:"targets"`
+}
+
+// RenderOptions controls how Render builds a MessageCard for an event.
+type RenderOptions struct {
+	// Channel is the Teams channel override understood by some incoming webhooks.
+	Channel string
+	// MessagePrefix is prepended to the card title, e.g. for mentions.
+	MessagePrefix string
+	// ActionName is the label of the "view in Sensu" action.
+	ActionName string
+	// DashboardURL is the base Sensu dashboard URL used to build the event link.
+	DashboardURL string
+	// SilenceURL, when set, adds a second potentialAction pointing at a silence endpoint.
+	SilenceURL string
+	// SilenceActionName is the label of the silence action. Defaults to "Silence".
+	SilenceActionName string
+	// Templates, when set, render the card title, text, and facts instead of
+	// the package defaults.
+	Templates *Templates
+	// MentionUsers is a list of UPNs/emails to @mention in the card text.
+	MentionUsers []string
+}
+
+// content is the title, text, facts, and @mention entities shared by every
+// card format Render* builds for an event.
+type content struct {
+	title    string
+	text     string
+	facts    []Fact
+	entities []MentionEntity
+}
+
+// renderContent resolves the title, text, facts, and @mentions for an event,
+// applying opts.Templates and opts.MentionUsers over the package defaults.
+func renderContent(event *types.Event, opts RenderOptions) (*content, error) {
+	if event == nil {
+		return nil, fmt.Errorf("event is nil")
+	}
+	if event.Check == nil {
+		return nil, fmt.Errorf("event has no check")
+	}
+	if event.Entity == nil {
+		return nil, fmt.Errorf("event has no entity")
+	}
+
+	title := fmt.Sprintf("%s/%s is %s", event.Entity.Name, event.Check.Name, statusText(event.Check.Status))
+	text := event.Check.Output
+	eventFacts := facts(event)
+
+	if opts.Templates != nil {
+		var err error
+		if title, text, eventFacts, err = opts.Templates.Execute(event); err != nil {
+			return nil, err
+		}
+	}
+	title = opts.MessagePrefix + title
+
+	var entities []MentionEntity
+	if len(opts.MentionUsers) > 0 {
+		var mentions []string
+		for _, user := range opts.MentionUsers {
+			tag := fmt.Sprintf("<at>%s</at>", user)
+			mentions = append(mentions, tag)
+			entities = append(entities, MentionEntity{
+				Type:      "mention",
+				Text:      tag,
+				Mentioned: Mentioned{ID: user, Name: user},
+			})
+		}
+		text = strings.Join(mentions, " ") + "\n\n" + text
+	}
+
+	return &content{title: title, text: text, facts: eventFacts, entities: entities}, nil
+}
+
+// Render builds a MessageCard describing the given Sensu event.
+func Render(event *types.Event, opts RenderOptions) (*MessageCard, error) {
+	c, err := renderContent(event, opts)
+	if err != nil {
+		return nil, err
+	}
+	title, text, eventFacts, entities := c.title, c.text, c.facts, c.entities
+
+	card := &MessageCard{
+		Type:       cardType,
+		Context:    cardContext,
+		Summary:    title,
+		ThemeColor: statusColor(event.Check.Status),
+		Channel:    opts.Channel,
+		Sections: []Section{
+			{
+				ActivityTitle: title,
+				Text:          text,
+				Facts:         eventFacts,
+			},
+		},
+	}
+
+	if len(entities) > 0 {
+		card.MSTeams = &MSTeams{Entities: entities}
+	}
+
+	card.PotentialAction = append(card.PotentialAction, PotentialAction{
+		Type:    "OpenUri",
+		Name:    opts.ActionName,
+		Targets: []Target{{OS: "default", URI: dashboardLink(opts.DashboardURL, event)}},
+	})
+
+	if opts.SilenceURL != "" {
+		name := opts.SilenceActionName
+		if name == "" {
+			name = "Silence"
+		}
+		card.PotentialAction = append(card.PotentialAction, PotentialAction{
+			Type:    "OpenUri",
+			Name:    name,
+			Targets: []Target{{OS: "default", URI: opts.SilenceURL}},
+		})
+	}
+
+	return card, nil
+}
+
+func facts(event *types.Event) []Fact {
+	f := []Fact{
+		{Name: "Entity", Value: event.Entity.Name},
+		{Name: "Namespace", Value: event.Entity.Namespace},
+		{Name: "Check", Value: event.Check.Name},
+		{Name: "Status", Value: statusText(event.Check.Status)},
+		{Name: "Occurrences", Value: fmt.Sprintf("%d", event.Check.Occurrences)},
+		{Name: "Silenced", Value: fmt.Sprintf("%t", event.IsSilenced())},
+	}
+
+	if event.Check.Executed != 0 {
+		f = append(f, Fact{Name: "Timestamp", Value: time.Unix(event.Check.Executed, 0).UTC().Format(time.RFC3339)})
+	}
+
+	if len(event.Check.Subscriptions) > 0 {
+		f = append(f, Fact{Name: "Subscriptions", Value: strings.Join(event.Check.Subscriptions, ", ")})
+	}
+
+	return f
+}
+
+func dashboardLink(dashboard string, event *types.Event) string {
+	dashboardURL, err := url.Parse(dashboard)
+	if err != nil {
+		return ""
+	}
+
+	eventPath, err := url.Parse(event.URIPath())
+	if err != nil {
+		return ""
+	}
+
+	return dashboardURL.ResolveReference(eventPath).String()
+}
+
+func statusColor(status uint32) string {
+	switch status {
+	case 0:
+		return "#36A64F"
+	case 1:
+		return "#FFCC00"
+	case 2:
+		return "#FF0000"
+	default:
+		return "#6600CC"
+	}
+}
+
+func statusText(status uint32) string {
+	switch status {
+	case 0:
+		return "RESOLVED"
+	case 1:
+		return "WARNING"
+	case 2:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,124 @@
+package teams
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+// Default templates mirror the handler's built-in (non-templated) card
+// output, i.e. the facts built by facts() in teams.go: Timestamp and
+// Subscriptions are only rendered when the event actually has them.
+const (
+	DefaultTitleTemplate = `{{.Entity.Name}}/{{.Check.Name}} is {{status .Check.Status}}`
+	DefaultTextTemplate  = `{{.Check.Output}}`
+	DefaultFactsTemplate = `Entity: {{.Entity.Name}}
+Namespace: {{.Entity.Namespace}}
+Check: {{.Check.Name}}
+Status: {{status .Check.Status}}
+Occurrences: {{.Check.Occurrences}}
+Silenced: {{.IsSilenced}}
+{{- if .Check.Executed}}
+Timestamp: {{rfc3339 .Check.Executed}}
+{{- end}}
+{{- if .Check.Subscriptions}}
+Subscriptions: {{join .Check.Subscriptions}}
+{{- end}}`
+)
+
+var funcMap = template.FuncMap{
+	"status":  statusText,
+	"rfc3339": func(sec int64) string { return time.Unix(sec, 0).UTC().Format(time.RFC3339) },
+	"join":    func(s []string) string { return strings.Join(s, ", ") },
+}
+
+// Templates holds the compiled title, text, and facts templates used to
+// render a MessageCard for an event.
+type Templates struct {
+	title *template.Template
+	text  *template.Template
+	facts *template.Template
+}
+
+// ParseTemplates compiles the title, text, and facts template sources. An
+// empty source falls back to the corresponding Default*Template.
+func ParseTemplates(title, text, facts string) (*Templates, error) {
+	if title == "" {
+		title = DefaultTitleTemplate
+	}
+	if text == "" {
+		text = DefaultTextTemplate
+	}
+	if facts == "" {
+		facts = DefaultFactsTemplate
+	}
+
+	var (
+		t   Templates
+		err error
+	)
+
+	if t.title, err = template.New("title").Funcs(funcMap).Parse(title); err != nil {
+		return nil, fmt.Errorf("failed to parse title template: %s", err.Error())
+	}
+	if t.text, err = template.New("text").Funcs(funcMap).Parse(text); err != nil {
+		return nil, fmt.Errorf("failed to parse text template: %s", err.Error())
+	}
+	if t.facts, err = template.New("facts").Funcs(funcMap).Parse(facts); err != nil {
+		return nil, fmt.Errorf("failed to parse facts template: %s", err.Error())
+	}
+
+	return &t, nil
+}
+
+// Execute renders the title, text, and facts templates against event. The
+// facts template's output is parsed as one "Name: Value" fact per line.
+func (t *Templates) Execute(event *types.Event) (title, text string, facts []Fact, err error) {
+	if title, err = executeTemplate(t.title, event); err != nil {
+		return "", "", nil, fmt.Errorf("failed to render title template: %s", err.Error())
+	}
+	if text, err = executeTemplate(t.text, event); err != nil {
+		return "", "", nil, fmt.Errorf("failed to render text template: %s", err.Error())
+	}
+
+	factsText, err := executeTemplate(t.facts, event)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to render facts template: %s", err.Error())
+	}
+
+	return title, text, parseFacts(factsText), nil
+}
+
+func executeTemplate(tmpl *template.Template, event *types.Event) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parseFacts splits a rendered facts template into Facts, one per non-empty
+// "Name: Value" line.
+func parseFacts(s string) []Fact {
+	var out []Fact
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		out = append(out, Fact{Name: strings.TrimSpace(parts[0]), Value: strings.TrimSpace(parts[1])})
+	}
+
+	return out
+}
@@ -0,0 +1,140 @@
+package teams
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sensu/sensu-go/types"
+)
+
+func testEvent() *types.Event {
+	return &types.Event{
+		Entity: &types.Entity{
+			ObjectMeta: types.ObjectMeta{Name: "web-1", Namespace: "default"},
+		},
+		Check: &types.Check{
+			ObjectMeta:  types.ObjectMeta{Name: "disk-usage"},
+			Status:      1,
+			Output:      "disk is 92% full",
+			Occurrences: 3,
+		},
+	}
+}
+
+func TestParseTemplatesRejectsBadSyntax(t *testing.T) {
+	if _, err := ParseTemplates("{{.Entity.Name", "", ""); err == nil {
+		t.Fatal("expected an error for an unterminated title template action")
+	}
+
+	if _, err := ParseTemplates("", "{{.Check.Output", ""); err == nil {
+		t.Fatal("expected an error for an unterminated text template action")
+	}
+
+	if _, err := ParseTemplates("", "", "{{.Check.Name"); err == nil {
+		t.Fatal("expected an error for an unterminated facts template action")
+	}
+}
+
+func TestParseTemplatesFallsBackToDefaults(t *testing.T) {
+	tmpl, err := ParseTemplates("", "", "")
+	if err != nil {
+		t.Fatalf("ParseTemplates returned error: %s", err.Error())
+	}
+
+	title, text, facts, err := tmpl.Execute(testEvent())
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err.Error())
+	}
+
+	if title != "web-1/disk-usage is WARNING" {
+		t.Errorf("title = %q, want default rendering", title)
+	}
+	if text != "disk is 92% full" {
+		t.Errorf("text = %q, want default rendering", text)
+	}
+
+	want := []Fact{
+		{Name: "Entity", Value: "web-1"},
+		{Name: "Namespace", Value: "default"},
+		{Name: "Check", Value: "disk-usage"},
+		{Name: "Status", Value: "WARNING"},
+		{Name: "Occurrences", Value: "3"},
+		{Name: "Silenced", Value: "false"},
+	}
+	if !reflect.DeepEqual(facts, want) {
+		t.Errorf("facts = %+v, want %+v", facts, want)
+	}
+}
+
+// TestParseTemplatesDefaultFactsMatchNonTemplatedOutput guards against the
+// default facts template silently dropping facts that facts() in teams.go
+// includes, since ParseTemplates is always invoked in the real binary and
+// the non-templated facts() path is otherwise dead code.
+func TestParseTemplatesDefaultFactsMatchNonTemplatedOutput(t *testing.T) {
+	tmpl, err := ParseTemplates("", "", "")
+	if err != nil {
+		t.Fatalf("ParseTemplates returned error: %s", err.Error())
+	}
+
+	event := testEvent()
+	event.Check.Executed = 1700000000
+	event.Check.Subscriptions = []string{"linux", "web"}
+
+	_, _, templated, err := tmpl.Execute(event)
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(templated, facts(event)) {
+		t.Errorf("default facts template rendered %+v, want facts() output %+v", templated, facts(event))
+	}
+}
+
+func TestExecuteFailsOnFieldMissingFromEvent(t *testing.T) {
+	tmpl, err := ParseTemplates("{{.Check.NoSuchField}}", "", "")
+	if err != nil {
+		t.Fatalf("ParseTemplates returned error: %s", err.Error())
+	}
+
+	if _, _, _, err := tmpl.Execute(testEvent()); err == nil {
+		t.Fatal("expected Execute to fail for a title template referencing a nonexistent field")
+	} else if !strings.Contains(err.Error(), "title") {
+		t.Errorf("error %q should identify which template failed", err.Error())
+	}
+}
+
+func TestExecuteEmptyFactsTemplateProducesNoFacts(t *testing.T) {
+	tmpl, err := ParseTemplates("", "", "")
+	if err != nil {
+		t.Fatalf("ParseTemplates returned error: %s", err.Error())
+	}
+
+	// Override with a facts template that renders to nothing but whitespace.
+	tmpl, err = ParseTemplates("", "", "   \n\n  ")
+	if err != nil {
+		t.Fatalf("ParseTemplates returned error: %s", err.Error())
+	}
+
+	_, _, facts, err := tmpl.Execute(testEvent())
+	if err != nil {
+		t.Fatalf("Execute returned error: %s", err.Error())
+	}
+	if len(facts) != 0 {
+		t.Errorf("expected no facts from a blank facts template, got %v", facts)
+	}
+}
+
+func TestParseFactsIgnoresMalformedLines(t *testing.T) {
+	facts := parseFacts("Entity: web-1\nnot a fact\n\nStatus: WARNING\n")
+
+	if len(facts) != 2 {
+		t.Fatalf("expected 2 well-formed facts, got %d: %v", len(facts), facts)
+	}
+	if facts[0] != (Fact{Name: "Entity", Value: "web-1"}) {
+		t.Errorf("facts[0] = %+v, want Entity/web-1", facts[0])
+	}
+	if facts[1] != (Fact{Name: "Status", Value: "WARNING"}) {
+		t.Errorf("facts[1] = %+v, want Status/WARNING", facts[1])
+	}
+}